@@ -7,9 +7,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
@@ -75,6 +77,8 @@ func ParseEpub(path string) ([]Document, error) {
 		"author":      book.Creator,
 		"subject":     book.Subject,
 		"description": book.Description,
+		"source_path": path,
+		"mime_type":   "application/epub+zip",
 	})
 }
 
@@ -85,6 +89,7 @@ type Document struct {
 
 type Result struct {
 	Document
+	ID    string
 	Score float32
 }
 
@@ -114,11 +119,34 @@ func (o ollamaEmbedder) Embed(ctx context.Context, text string) ([]float32, erro
 	return embeddings, nil
 }
 
+// retrievalMode selects how chromemStore.Search combines the dense
+// (vector) and lexical (BM25) rankers.
+type retrievalMode int
+
+const (
+	// modeHybrid fuses both rankers via Reciprocal Rank Fusion, or a
+	// weighted sum of normalized scores once Query.WithHybrid has set Alpha.
+	modeHybrid retrievalMode = iota
+	modeVectorOnly
+	modeBM25Only
+)
+
+// rrfK is the rank-damping constant used by Reciprocal Rank Fusion, per
+// the original RRF paper's recommended default.
+const rrfK = 60
+
 type Query struct {
 	Query  string
 	Exact  map[string]string
 	Regex  map[string]*regexp.Regexp
 	Number int
+
+	Mode     retrievalMode
+	Alpha    float32
+	hasAlpha bool
+
+	RerankTopN int
+	hasRerank  bool
 }
 
 func NewQuery(query string) *Query {
@@ -170,6 +198,42 @@ func (q *Query) WithNumber(i int) *Query {
 	return q
 }
 
+// WithHybrid switches fusion from Reciprocal Rank Fusion to a weighted
+// sum of normalized scores: alpha*vectorScore + (1-alpha)*bm25Score.
+func (q *Query) WithHybrid(alpha float32) *Query {
+	q = q.setOrDefault()
+	q.Mode = modeHybrid
+	q.Alpha = alpha
+	q.hasAlpha = true
+	return q
+}
+
+// WithBM25Only restricts Search to the lexical BM25 ranker, useful for
+// debugging retrieval quality in isolation from the vector index.
+func (q *Query) WithBM25Only() *Query {
+	q = q.setOrDefault()
+	q.Mode = modeBM25Only
+	return q
+}
+
+// WithVectorOnly restricts Search to the dense vector ranker, useful for
+// debugging retrieval quality in isolation from the BM25 index.
+func (q *Query) WithVectorOnly() *Query {
+	q = q.setOrDefault()
+	q.Mode = modeVectorOnly
+	return q
+}
+
+// WithRerank asks the store to fetch a wider pool of candidates, rerank
+// them, and return only the top topN. It is a no-op unless the store was
+// built with NewRerankingStore.
+func (q *Query) WithRerank(topN int) *Query {
+	q = q.setOrDefault()
+	q.RerankTopN = topN
+	q.hasRerank = true
+	return q
+}
+
 func (q *Query) Filter(r Result) bool {
 	md := r.Document.Metadata
 	switch {
@@ -196,10 +260,19 @@ type VectorStore interface {
 
 type chromemStore struct {
 	collection  *chromem.Collection
+	bm25        *bm25Index
 	concurrency int
 }
 
-func NewCollection(path, collection string, e Embedder) (VectorStore, error) {
+// NewCollection opens a VectorStore at location. A grpc://host:port/tenant/database/collection
+// location talks to a server speaking advisory's own gRPC protocol (see
+// chromagrpc.go); anything else (a bare filesystem path, or one prefixed
+// with file://) is opened as an embedded chromem-go DB named collection.
+func NewCollection(location, collection string, e Embedder) (VectorStore, error) {
+	if strings.HasPrefix(location, "grpc://") {
+		return newGRPCStore(location, e)
+	}
+	path := strings.TrimPrefix(location, "file://")
 	db, err := chromem.NewPersistentDB(path, false)
 	if err != nil {
 		return nil, err
@@ -208,7 +281,11 @@ func NewCollection(path, collection string, e Embedder) (VectorStore, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &chromemStore{collection: c, concurrency: runtime.NumCPU()}, nil
+	bm25, err := newBM25Index(filepath.Join(path, collection+".bm25.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &chromemStore{collection: c, bm25: bm25, concurrency: runtime.NumCPU()}, nil
 }
 
 func md5hash(s string) string {
@@ -234,11 +311,22 @@ func (c *chromemStore) Add(ctx context.Context, docs ...Document) error {
 		}()
 	}
 	wg.Wait()
-	return c.collection.AddDocuments(ctx, documents, c.concurrency)
+	if err := c.collection.AddDocuments(ctx, documents, c.concurrency); err != nil {
+		return err
+	}
+	for _, doc := range documents {
+		if err := c.bm25.Add(doc.ID, Document{Content: doc.Content, Metadata: doc.Metadata}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (c *chromemStore) Search(ctx context.Context, query *Query) ([]Result, error) {
-	q := query.setOrDefault()
+// vectorSearch runs the dense chromem-go query for q, independent of
+// q.Mode, returning results with their ID set so callers can fuse them
+// against the BM25 ranker. Only q.Exact is applied (as the chromem-go
+// Where filter); Query.Filter is not.
+func (c *chromemStore) vectorSearch(ctx context.Context, q *Query) ([]Result, error) {
 	opt := chromem.QueryOptions{
 		QueryText: q.Query,
 		Where:     q.Exact,
@@ -248,18 +336,56 @@ func (c *chromemStore) Search(ctx context.Context, query *Query) ([]Result, erro
 	if err != nil {
 		return nil, err
 	}
-	results := make([]Result, 0, len(res))
-	for _, r := range res {
-		result := Result{
+	results := make([]Result, len(res))
+	for i, r := range res {
+		results[i] = Result{
+			ID: md5hash(r.Content),
 			Document: Document{
 				Metadata: r.Metadata,
 				Content:  r.Content,
 			},
 			Score: r.Similarity,
 		}
-		if query.Filter(result) {
-			results = append(results, result)
+	}
+	return results, nil
+}
+
+func (c *chromemStore) Search(ctx context.Context, query *Query) ([]Result, error) {
+	q := query.setOrDefault()
+
+	var vecResults, bm25Results []Result
+	var err error
+	if q.Mode != modeBM25Only {
+		if vecResults, err = c.vectorSearch(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+	if q.Mode != modeVectorOnly {
+		bm25Results = c.bm25.Search(q.Query, q.Number)
+	}
+
+	var fused []Result
+	switch q.Mode {
+	case modeVectorOnly:
+		fused = vecResults
+	case modeBM25Only:
+		fused = bm25Results
+	default:
+		if q.hasAlpha {
+			fused = fuseWeighted(vecResults, bm25Results, q.Alpha)
+		} else {
+			fused = fuseRRF(vecResults, bm25Results)
+		}
+	}
+
+	results := make([]Result, 0, len(fused))
+	for _, r := range fused {
+		if query.Filter(r) {
+			results = append(results, r)
 		}
 	}
+	if len(results) > q.Number {
+		results = results[:q.Number]
+	}
 	return results, nil
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,8 +10,28 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(context.Context, string) ([]float32, error) {
+	return nil, errors.New("embed failed")
+}
+
+// Test_chromemStore_Add_bm25NotPersistedOnFailure guards against the BM25
+// index gaining "ghost" entries for documents that failed to make it into
+// the vector collection: the BM25 write must only happen once
+// AddDocuments has actually succeeded.
+func Test_chromemStore_Add_bm25NotPersistedOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewCollection(dir, "default", failingEmbedder{})
+	require.NoError(t, err)
+	c := store.(*chromemStore)
+	err = c.Add(t.Context(), Document{Content: "doc that will fail to embed"})
+	require.Error(t, err)
+	require.Empty(t, c.bm25.Docs)
+}
+
 func Test_chromemStore(t *testing.T) {
-	e, err := NewOllamaEmbedder(embeddingModel)
+	e, err := NewOllamaEmbedder(defaultEmbeddingModel)
 	require.NoError(t, err)
 	dir, err := os.MkdirTemp("", "chromem_test")
 	require.NoError(t, err)
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	bm25K1 = 1.5
+	bm25B  = 0.75
+)
+
+var tokenPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// stopwords are the common English terms stripped before indexing or
+// querying, since they carry little signal for BM25 ranking.
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "will": true, "with": true,
+}
+
+// stem applies a small set of common English suffix-stripping rules in
+// the spirit of the Porter stemmer, trading completeness for simplicity.
+func stem(word string) string {
+	for _, suffix := range []string{"ational", "ization", "fulness", "ousness", "iveness", "ing", "edly", "ed", "es", "ly", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word) > len(suffix)+2 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// tokenize lowercases text, splits on unicode letter/number runs, strips
+// stopwords, and stems what remains.
+func tokenize(text string) []string {
+	matches := tokenPattern.FindAllString(strings.ToLower(text), -1)
+	tokens := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if stopwords[m] {
+			continue
+		}
+		tokens = append(tokens, stem(m))
+	}
+	return tokens
+}
+
+// bm25Document is a single indexed document: its content/metadata for
+// returning as a Result, plus the term frequencies BM25 scores against.
+type bm25Document struct {
+	Content  string
+	Metadata map[string]string
+	Length   int
+	TermFreq map[string]int
+}
+
+// bm25Index is a lexical BM25 index persisted to disk next to a
+// collection's chromem-go store, so Search can fuse dense and lexical
+// retrieval for the same collection.
+type bm25Index struct {
+	path string
+
+	mu          sync.Mutex
+	Docs        map[string]*bm25Document
+	DocFreq     map[string]int
+	TotalDocLen int
+}
+
+// newBM25Index loads the index persisted at path, or creates a new one
+// if no file exists yet.
+func newBM25Index(path string) (*bm25Index, error) {
+	idx := &bm25Index{path: path, Docs: make(map[string]*bm25Document), DocFreq: make(map[string]int)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *bm25Index) save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(idx.path, data, 0644)
+}
+
+// Add tokenizes doc and updates the term/document-frequency tables,
+// replacing any existing entry for the same id.
+func (idx *bm25Index) Add(id string, doc Document) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, ok := idx.Docs[id]; ok {
+		idx.TotalDocLen -= old.Length
+		for term := range old.TermFreq {
+			idx.DocFreq[term]--
+		}
+	}
+
+	termFreq := make(map[string]int)
+	tokens := tokenize(doc.Content)
+	for _, t := range tokens {
+		termFreq[t]++
+	}
+	for term := range termFreq {
+		idx.DocFreq[term]++
+	}
+	idx.Docs[id] = &bm25Document{
+		Content:  doc.Content,
+		Metadata: doc.Metadata,
+		Length:   len(tokens),
+		TermFreq: termFreq,
+	}
+	idx.TotalDocLen += len(tokens)
+	return idx.save()
+}
+
+func (idx *bm25Index) avgDocLen() float64 {
+	if len(idx.Docs) == 0 {
+		return 0
+	}
+	return float64(idx.TotalDocLen) / float64(len(idx.Docs))
+}
+
+func (idx *bm25Index) idf(term string) float64 {
+	n := float64(len(idx.Docs))
+	df := float64(idx.DocFreq[term])
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// Search scores every indexed document against query's terms and
+// returns the top n by descending BM25 score.
+func (idx *bm25Index) Search(query string, n int) []Result {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	terms := tokenize(query)
+	avgdl := idx.avgDocLen()
+	type scored struct {
+		id    string
+		score float32
+	}
+	scores := make([]scored, 0, len(idx.Docs))
+	for id, doc := range idx.Docs {
+		var score float64
+		for _, term := range terms {
+			tf := float64(doc.TermFreq[term])
+			if tf == 0 {
+				continue
+			}
+			idf := idx.idf(term)
+			score += idf * (tf * (bm25K1 + 1)) / (tf + bm25K1*(1-bm25B+bm25B*float64(doc.Length)/avgdl))
+		}
+		if score > 0 {
+			scores = append(scores, scored{id: id, score: float32(score)})
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].score > scores[j].score })
+	if len(scores) > n {
+		scores = scores[:n]
+	}
+	results := make([]Result, len(scores))
+	for i, s := range scores {
+		doc := idx.Docs[s.id]
+		results[i] = Result{
+			ID:       s.id,
+			Document: Document{Content: doc.Content, Metadata: doc.Metadata},
+			Score:    s.score,
+		}
+	}
+	return results
+}
+
+// fuseRRF combines vector and bm25 result lists via Reciprocal Rank
+// Fusion: each result's contribution is 1/(rrfK+rank), summed across the
+// lists it appears in, then sorted descending by the summed score.
+func fuseRRF(vector, bm25 []Result) []Result {
+	byID := make(map[string]Result)
+	rrfScore := make(map[string]float64)
+	for _, list := range [][]Result{vector, bm25} {
+		for rank, r := range list {
+			byID[r.ID] = r
+			rrfScore[r.ID] += 1 / float64(rrfK+rank+1)
+		}
+	}
+	return sortFused(byID, rrfScore)
+}
+
+// fuseWeighted combines vector and bm25 result lists as
+// alpha*normalizedVectorScore + (1-alpha)*normalizedBM25Score, each list
+// normalized to [0, 1] by its own max score before weighting.
+func fuseWeighted(vector, bm25 []Result, alpha float32) []Result {
+	byID := make(map[string]Result)
+	score := make(map[string]float64)
+	for _, r := range vector {
+		byID[r.ID] = r
+	}
+	for _, r := range bm25 {
+		byID[r.ID] = r
+	}
+	for _, r := range normalize(vector) {
+		score[r.ID] += float64(alpha) * float64(r.Score)
+	}
+	for _, r := range normalize(bm25) {
+		score[r.ID] += float64(1-alpha) * float64(r.Score)
+	}
+	return sortFused(byID, score)
+}
+
+// normalize rescales results' scores into [0, 1] by dividing by the
+// maximum score in the list, so lists on different scales can be
+// weighted together.
+func normalize(results []Result) []Result {
+	var max float32
+	for _, r := range results {
+		if r.Score > max {
+			max = r.Score
+		}
+	}
+	if max == 0 {
+		return results
+	}
+	normalized := make([]Result, len(results))
+	for i, r := range results {
+		r.Score /= max
+		normalized[i] = r
+	}
+	return normalized
+}
+
+// sortFused orders the ids in scores by descending score and returns the
+// corresponding results from byID.
+func sortFused(byID map[string]Result, scores map[string]float64) []Result {
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return scores[ids[i]] > scores[ids[j]] })
+	fused := make([]Result, len(ids))
+	for i, id := range ids {
+		r := byID[id]
+		r.Score = float32(scores[id])
+		fused[i] = r
+	}
+	return fused
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_tokenize(t *testing.T) {
+	tokens := tokenize("The Rayleigh scattering is occurring!")
+	require.NotContains(t, tokens, "the")
+	require.NotContains(t, tokens, "is")
+	require.Contains(t, tokens, "rayleigh")
+	require.Contains(t, tokens, "scatter")
+}
+
+func Test_bm25Index(t *testing.T) {
+	idx, err := newBM25Index(filepath.Join(t.TempDir(), "bm25.json"))
+	require.NoError(t, err)
+	require.NoError(t, idx.Add("1", Document{Content: "The sky is blue because of Rayleigh scattering."}))
+	require.NoError(t, idx.Add("2", Document{Content: "Leaves are green because chlorophyll absorbs red and blue light."}))
+
+	results := idx.Search("Why is the sky blue?", 10)
+	require.NotEmpty(t, results)
+	require.Equal(t, "1", results[0].ID)
+}
+
+func Test_fuseRRF(t *testing.T) {
+	vector := []Result{{ID: "a"}, {ID: "b"}}
+	bm25 := []Result{{ID: "b"}, {ID: "c"}}
+	fused := fuseRRF(vector, bm25)
+	require.Len(t, fused, 3)
+	require.Equal(t, "b", fused[0].ID)
+}
+
+func Test_fuseWeighted(t *testing.T) {
+	vector := []Result{{ID: "a", Score: 1}}
+	bm25 := []Result{{ID: "b", Score: 1}}
+	fused := fuseWeighted(vector, bm25, 1)
+	require.Equal(t, "a", fused[0].ID)
+
+	fused = fuseWeighted(vector, bm25, 0)
+	require.Equal(t, "b", fused[0].ID)
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// This file is a client for a custom gRPC wire protocol of advisory's
+// own invention. It is NOT Chroma's gRPC API: advisory doesn't vendor
+// Chroma's generated protobuf stubs, and nothing in this repo speaks
+// the real chromadb wire protocol, so a grpc:// location will not work
+// against an actual Chroma server. No server implementing this protocol
+// ships in this repo either; chromagrpc_test.go's fake server is the
+// only thing that currently speaks it.
+
+// Collection is advisory's own message shape for naming a store
+// collection; it has no relation to Chroma's Collection message.
+type Collection struct {
+	Id       string
+	Name     string
+	Tenant   string
+	Database string
+	Metadata map[string]string
+}
+
+// Vector is advisory's own message shape: an embedding plus the document
+// and metadata it was derived from.
+type Vector struct {
+	Id        string
+	Embedding []float32
+	Document  string
+	Metadata  map[string]string
+}
+
+// QueryEmbedding is advisory's own message shape for a similarity query
+// request.
+type QueryEmbedding struct {
+	CollectionId  string
+	Embedding     []float32
+	NResults      int32
+	Where         map[string]string
+	WhereDocument map[string]string
+}
+
+// Without generated protobuf stubs, the grpc codec (which requires
+// proto.Message) has nothing to marshal the structs above with
+// directly. toProtoStruct/fromProtoStruct round-trip them through
+// google.protobuf.Struct instead, which is a real proto.Message the
+// default codec can handle.
+
+func toProtoStruct(v any) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return structpb.NewStruct(m)
+}
+
+func fromProtoStruct(s *structpb.Struct, out any) error {
+	b, err := json.Marshal(s.AsMap())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// remoteStoreClient is the subset of advisory's remote store protocol
+// that grpcStore depends on.
+type remoteStoreClient interface {
+	GetOrCreateCollection(ctx context.Context, tenant, database, name string) (*Collection, error)
+	Upsert(ctx context.Context, collectionID string, vectors []Vector) error
+	Query(ctx context.Context, q *QueryEmbedding) ([]Vector, []float32, error)
+}
+
+// grpcRemoteStoreClient talks advisory's own RemoteStore protocol (see
+// the package doc above). No server implementing it ships in this repo;
+// chromagrpc_test.go's fakeRemoteStore is the only thing this client has
+// ever been exercised against.
+type grpcRemoteStoreClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *grpcRemoteStoreClient) GetOrCreateCollection(ctx context.Context, tenant, database, name string) (*Collection, error) {
+	col := &Collection{Name: name, Tenant: tenant, Database: database}
+	req, err := toProtoStruct(col)
+	if err != nil {
+		return nil, err
+	}
+	resp := &structpb.Struct{}
+	if err := c.conn.Invoke(ctx, "/advisory.RemoteStore/GetOrCreateCollection", req, resp); err != nil {
+		return nil, err
+	}
+	if err := fromProtoStruct(resp, col); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+func (c *grpcRemoteStoreClient) Upsert(ctx context.Context, collectionID string, vectors []Vector) error {
+	req, err := toProtoStruct(struct {
+		CollectionId string
+		Vectors      []Vector
+	}{CollectionId: collectionID, Vectors: vectors})
+	if err != nil {
+		return err
+	}
+	return c.conn.Invoke(ctx, "/advisory.RemoteStore/Upsert", req, &structpb.Struct{})
+}
+
+func (c *grpcRemoteStoreClient) Query(ctx context.Context, q *QueryEmbedding) ([]Vector, []float32, error) {
+	req, err := toProtoStruct(q)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := &structpb.Struct{}
+	if err := c.conn.Invoke(ctx, "/advisory.RemoteStore/QueryEmbeddings", req, resp); err != nil {
+		return nil, nil, err
+	}
+	var out struct {
+		Vectors   []Vector
+		Distances []float32
+	}
+	if err := fromProtoStruct(resp, &out); err != nil {
+		return nil, nil, err
+	}
+	return out.Vectors, out.Distances, nil
+}
+
+// grpcStore is a VectorStore backed by a server speaking advisory's own
+// gRPC protocol (see the package comment above), as an alternative to
+// the embedded chromemStore.
+type grpcStore struct {
+	client     remoteStoreClient
+	collection *Collection
+	embedder   Embedder
+}
+
+// parseGRPCLocation parses a grpc://host:port/tenant/database/collection
+// URL into its dial target and path components.
+func parseGRPCLocation(location string) (target, tenant, database, collection string, err error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 {
+		return "", "", "", "", fmt.Errorf("grpc store url %q: expected /tenant/database/collection path, got %q", location, u.Path)
+	}
+	return u.Host, parts[0], parts[1], parts[2], nil
+}
+
+// newGRPCStore dials a server speaking advisory's protocol at a
+// grpc://host:port/tenant/database/collection URL and returns a
+// VectorStore backed by it. Queries are embedded client-side using e,
+// matching chromemStore's behavior.
+func newGRPCStore(location string, e Embedder) (VectorStore, error) {
+	target, tenant, database, collection, err := parseGRPCLocation(location)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	client := &grpcRemoteStoreClient{conn: conn}
+	col, err := client.GetOrCreateCollection(context.Background(), tenant, database, collection)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcStore{client: client, collection: col, embedder: e}, nil
+}
+
+func (c *grpcStore) Add(ctx context.Context, docs ...Document) error {
+	vectors := make([]Vector, len(docs))
+	for i, doc := range docs {
+		embedding, err := c.embedder.Embed(ctx, doc.Content)
+		if err != nil {
+			return err
+		}
+		vectors[i] = Vector{
+			Id:        md5hash(doc.Content),
+			Embedding: embedding,
+			Document:  doc.Content,
+			Metadata:  doc.Metadata,
+		}
+	}
+	return c.client.Upsert(ctx, c.collection.Id, vectors)
+}
+
+// whereFromQuery translates Query.Exact into the Where filter of the
+// QueryEmbeddings request. WhereDocument is never set: Regex constraints
+// are not pushed down server-side, so they're left for Query.Filter to
+// apply after the fact.
+func whereFromQuery(q *Query) map[string]string {
+	if len(q.Exact) == 0 {
+		return nil
+	}
+	return q.Exact
+}
+
+func (c *grpcStore) Search(ctx context.Context, query *Query) ([]Result, error) {
+	q := query.setOrDefault()
+	embedding, err := c.embedder.Embed(ctx, q.Query)
+	if err != nil {
+		return nil, err
+	}
+	vectors, distances, err := c.client.Query(ctx, &QueryEmbedding{
+		CollectionId: c.collection.Id,
+		Embedding:    embedding,
+		NResults:     int32(q.Number),
+		Where:        whereFromQuery(q),
+	})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, 0, len(vectors))
+	for i, v := range vectors {
+		result := Result{
+			ID:       v.Id,
+			Document: Document{Metadata: v.Metadata, Content: v.Document},
+			Score:    distances[i],
+		}
+		if query.Filter(result) {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func Test_parseGRPCLocation(t *testing.T) {
+	target, tenant, database, collection, err := parseGRPCLocation("grpc://localhost:8000/default_tenant/default_database/books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "localhost:8000" {
+		t.Errorf("target = %q, want %q", target, "localhost:8000")
+	}
+	if tenant != "default_tenant" || database != "default_database" || collection != "books" {
+		t.Errorf("got tenant=%q database=%q collection=%q", tenant, database, collection)
+	}
+}
+
+func Test_parseGRPCLocation_invalid(t *testing.T) {
+	if _, _, _, _, err := parseGRPCLocation("grpc://localhost:8000/books"); err == nil {
+		t.Error("expected error for missing tenant/database segments")
+	}
+}
+
+func Test_whereFromQuery(t *testing.T) {
+	if got := whereFromQuery(NewQuery("q")); got != nil {
+		t.Errorf("whereFromQuery() = %v, want nil", got)
+	}
+	q := NewQuery("q").WithExact("author", "Orwell")
+	if got := whereFromQuery(q); got["author"] != "Orwell" {
+		t.Errorf("whereFromQuery() = %v, want author=Orwell", got)
+	}
+}
+
+// fakeRemoteStore is a reference implementation of advisory's own
+// RemoteStore protocol (see chromagrpc.go) — not a stand-in for Chroma's
+// gRPC service, which this codebase does not implement or talk to. It
+// exists only so grpcRemoteStoreClient has a server to exercise in
+// tests.
+type fakeRemoteStore struct {
+	collection *Collection
+	vectors    []Vector
+}
+
+func (s *fakeRemoteStore) getOrCreateCollection(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	var col Collection
+	if err := fromProtoStruct(req, &col); err != nil {
+		return nil, err
+	}
+	col.Id = "collection-1"
+	s.collection = &col
+	return toProtoStruct(col)
+}
+
+func (s *fakeRemoteStore) upsert(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	var in struct {
+		CollectionId string
+		Vectors      []Vector
+	}
+	if err := fromProtoStruct(req, &in); err != nil {
+		return nil, err
+	}
+	s.vectors = append(s.vectors, in.Vectors...)
+	return &structpb.Struct{}, nil
+}
+
+func (s *fakeRemoteStore) queryEmbeddings(_ context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	if _, err := req.MarshalJSON(); err != nil {
+		return nil, err
+	}
+	out := struct {
+		Vectors   []Vector
+		Distances []float32
+	}{}
+	for _, v := range s.vectors {
+		out.Vectors = append(out.Vectors, v)
+		out.Distances = append(out.Distances, 0.5)
+	}
+	return toProtoStruct(out)
+}
+
+// unaryHandler adapts a (ctx, *structpb.Struct) -> (*structpb.Struct, error)
+// method into the grpc.MethodHandler shape, since there's no generated
+// service descriptor to register the methods under.
+func unaryHandler(fn func(context.Context, *structpb.Struct) (*structpb.Struct, error)) grpc.MethodHandler {
+	return func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		req := &structpb.Struct{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return fn(ctx, req)
+	}
+}
+
+func newFakeRemoteStoreServer(t *testing.T, svc *fakeRemoteStore) *grpc.ClientConn {
+	t.Helper()
+	desc := grpc.ServiceDesc{
+		ServiceName: "advisory.RemoteStore",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "GetOrCreateCollection", Handler: unaryHandler(svc.getOrCreateCollection)},
+			{MethodName: "Upsert", Handler: unaryHandler(svc.upsert)},
+			{MethodName: "QueryEmbeddings", Handler: unaryHandler(svc.queryEmbeddings)},
+		},
+	}
+	srv := grpc.NewServer()
+	srv.RegisterService(&desc, svc)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// constantEmbedder is a test Embedder that always returns the same
+// embedding, so Add/Search don't need a real embedding model.
+type constantEmbedder struct{ embedding []float32 }
+
+func (e constantEmbedder) Embed(context.Context, string) ([]float32, error) {
+	return e.embedding, nil
+}
+
+func Test_grpcStore_AddSearch(t *testing.T) {
+	svc := &fakeRemoteStore{}
+	conn := newFakeRemoteStoreServer(t, svc)
+	client := &grpcRemoteStoreClient{conn: conn}
+
+	col, err := client.GetOrCreateCollection(t.Context(), "default_tenant", "default_database", "books")
+	if err != nil {
+		t.Fatalf("GetOrCreateCollection: %v", err)
+	}
+	if col.Id != "collection-1" {
+		t.Fatalf("collection id = %q, want %q", col.Id, "collection-1")
+	}
+
+	store := &grpcStore{client: client, collection: col, embedder: constantEmbedder{[]float32{0.1, 0.2}}}
+	doc := Document{Content: "the sky is blue", Metadata: map[string]string{"author": "Orwell"}}
+	if err := store.Add(t.Context(), doc); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if len(svc.vectors) != 1 || svc.vectors[0].Document != doc.Content {
+		t.Fatalf("server received vectors = %+v, want one vector for %q", svc.vectors, doc.Content)
+	}
+
+	results, err := store.Search(t.Context(), NewQuery("sky").WithNumber(1))
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 1 || results[0].Content != doc.Content {
+		t.Fatalf("Search() = %+v, want one result for %q", results, doc.Content)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/fumiama/go-docx"
+)
+
+// docxLoader extracts the paragraph text of a .docx file and chunks it
+// the same way as markdown.
+type docxLoader struct{}
+
+func (docxLoader) Extensions() []string { return []string{".docx"} }
+
+func (docxLoader) Load(path string) ([]Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := docx.Parse(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	for _, item := range doc.Document.Body.Items {
+		if p, ok := item.(*docx.Paragraph); ok {
+			sb.WriteString(p.String())
+			sb.WriteString("\n\n")
+		}
+	}
+	return chunkMarkdown([]byte(sb.String()), loaderMetadata(path, "application/vnd.openxmlformats-officedocument.wordprocessingml.document"))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fumiama/go-docx"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_docxLoader(t *testing.T) {
+	w := docx.New()
+	w.AddParagraph().AddText("Hello DOCX World")
+
+	buf := &bytes.Buffer{}
+	_, err := w.WriteTo(buf)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.docx")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+
+	docs, err := (docxLoader{}).Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+	require.Contains(t, docs[0].Content, "Hello DOCX World")
+	require.Equal(t, path, docs[0].Metadata["source_path"])
+	require.Equal(t, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", docs[0].Metadata["mime_type"])
+	require.Contains(t, docs[0].Metadata, "author")
+}
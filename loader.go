@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+)
+
+// Loader ingests a file on disk and produces the Documents to add to a
+// VectorStore.
+type Loader interface {
+	Load(path string) ([]Document, error)
+	Extensions() []string
+}
+
+var loaders = make(map[string]Loader)
+
+// RegisterLoader registers l for each of the extensions it reports via
+// Extensions (e.g. ".epub"). Later registrations for the same extension
+// replace earlier ones.
+func RegisterLoader(l Loader) {
+	for _, ext := range l.Extensions() {
+		loaders[strings.ToLower(ext)] = l
+	}
+}
+
+// LoaderFor returns the Loader registered for path's extension.
+func LoaderFor(path string) (Loader, error) {
+	return loaderForExt(filepath.Ext(path))
+}
+
+func loaderForExt(ext string) (Loader, error) {
+	ext = strings.ToLower(ext)
+	l, ok := loaders[ext]
+	if !ok {
+		return nil, fmt.Errorf("no loader registered for %q files", ext)
+	}
+	return l, nil
+}
+
+func init() {
+	RegisterLoader(epubLoader{})
+	RegisterLoader(plaintextLoader{})
+	RegisterLoader(htmlLoader{})
+	RegisterLoader(pdfLoader{})
+	RegisterLoader(docxLoader{})
+}
+
+type epubLoader struct{}
+
+func (epubLoader) Extensions() []string { return []string{".epub"} }
+func (epubLoader) Load(path string) ([]Document, error) {
+	return ParseEpub(path)
+}
+
+// plaintextLoader handles files that are already markdown or plaintext,
+// skipping the HTML-to-markdown conversion step.
+type plaintextLoader struct{}
+
+func (plaintextLoader) Extensions() []string { return []string{".md", ".txt"} }
+func (plaintextLoader) Load(path string) ([]Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return chunkMarkdown(content, loaderMetadata(path, "text/plain"))
+}
+
+type htmlLoader struct{}
+
+func (htmlLoader) Extensions() []string { return []string{".html", ".htm"} }
+func (htmlLoader) Load(path string) ([]Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	md, err := htmltomarkdown.ConvertReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return chunkMarkdown(md, loaderMetadata(path, "text/html"))
+}
+
+// loaderMetadata seeds the metadata all loaders populate uniformly so
+// downstream Query.Exact/Query.Regex filtering works across formats. The
+// title defaults to the file's base name; loaders with richer metadata
+// (e.g. ParseEpub) override it.
+func loaderMetadata(path, mimeType string) map[string]string {
+	return map[string]string{
+		"title":       filepath.Base(path),
+		"author":      "",
+		"source_path": path,
+		"mime_type":   mimeType,
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoaderFor(t *testing.T) {
+	for ext, want := range map[string]Loader{
+		".epub": epubLoader{},
+		".md":   plaintextLoader{},
+		".txt":  plaintextLoader{},
+		".html": htmlLoader{},
+		".htm":  htmlLoader{},
+		".pdf":  pdfLoader{},
+		".docx": docxLoader{},
+	} {
+		l, err := LoaderFor("book" + ext)
+		require.NoError(t, err)
+		require.IsType(t, want, l)
+	}
+	_, err := LoaderFor("book.unknown")
+	require.Error(t, err)
+}
+
+func Test_plaintextLoader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Title\n\nSome notes."), 0644))
+	docs, err := (plaintextLoader{}).Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+	require.Equal(t, path, docs[0].Metadata["source_path"])
+	require.Equal(t, "text/plain", docs[0].Metadata["mime_type"])
+	require.Contains(t, docs[0].Metadata, "author")
+}
+
+func Test_htmlLoader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "page.html")
+	require.NoError(t, os.WriteFile(path, []byte("<html><body><h1>Title</h1><p>Some text.</p></body></html>"), 0644))
+	docs, err := (htmlLoader{}).Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+	require.Equal(t, "text/html", docs[0].Metadata["mime_type"])
+	require.Contains(t, docs[0].Metadata, "author")
+}
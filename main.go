@@ -6,52 +6,117 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/huh/spinner"
 	"github.com/google/subcommands"
+	"github.com/ollama/ollama/api"
 )
 
 const (
 	defaultEmbeddingModel = "nomic-embed-text"
+	defaultGenModel       = "llama3.2"
 	defaultStoreName      = "default"
 )
 
+// keyValueFlag accumulates repeated -flag key=value arguments into a map,
+// for filters such as -where and -where-regex.
+type keyValueFlag map[string]string
+
+func (m keyValueFlag) String() string {
+	return fmt.Sprint(map[string]string(m))
+}
+
+func (m keyValueFlag) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	m[k] = v
+	return nil
+}
+
+func (m keyValueFlag) keyValues() []string {
+	kv := make([]string, 0, len(m)*2)
+	for k, v := range m {
+		kv = append(kv, k, v)
+	}
+	return kv
+}
+
 type chromaStoreCmd struct {
-	model, storeName string
+	model, storeName, storeURL, rerankModel string
 }
 
 func (c *chromaStoreCmd) SetFlags(f *flag.FlagSet) {
 	f.StringVar(&c.model, "model", defaultEmbeddingModel, "embedding model to use")
 	f.StringVar(&c.storeName, "collection", defaultStoreName, "collection of vectorstore")
+	f.StringVar(&c.storeURL, "store-url", "", "vectorstore location, e.g. grpc://host:port/tenant/database/collection (defaults to the local file store)")
+	f.StringVar(&c.rerankModel, "rerank-model", "", "Ollama model used to rerank search results (unset disables reranking)")
 }
 
 func (c *chromaStoreCmd) Store() (VectorStore, error) {
-	home, err := os.UserHomeDir()
+	e, err := NewOllamaEmbedder(c.model)
 	if err != nil {
 		return nil, err
 	}
-	storePath := filepath.Join(home, ".advisory", "store")
-	if err := os.MkdirAll(storePath, 0755); err != nil {
+	collection := c.model + "_" + c.storeName
+
+	store, err := c.newStore(collection, e)
+	if err != nil {
 		return nil, err
 	}
-	e, err := NewOllamaEmbedder(c.model)
+	if c.rerankModel == "" {
+		return store, nil
+	}
+	reranker, err := NewOllamaReranker(c.rerankModel)
 	if err != nil {
 		return nil, err
 	}
+	return NewRerankingStore(store, reranker), nil
+}
 
-	return NewCollection(storePath, c.model+"_"+c.storeName, e)
+func (c *chromaStoreCmd) newStore(collection string, e Embedder) (VectorStore, error) {
+	if c.storeURL != "" {
+		return NewCollection(c.storeURL, collection, e)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	storePath := filepath.Join(home, ".advisory", "store")
+	if err := os.MkdirAll(storePath, 0755); err != nil {
+		return nil, err
+	}
+	return NewCollection(storePath, collection, e)
 }
 
-type addCmd struct{ chromaStoreCmd }
+type addCmd struct {
+	chromaStoreCmd
+	loaderExt string
+}
 
 func (*addCmd) Name() string     { return "add" }
-func (*addCmd) Synopsis() string { return "add epub file content to vectorstore" }
+func (*addCmd) Synopsis() string { return "add file content to vectorstore" }
 func (*addCmd) Usage() string    { return "" }
 
+func (a *addCmd) SetFlags(f *flag.FlagSet) {
+	a.chromaStoreCmd.SetFlags(f)
+	f.StringVar(&a.loaderExt, "loader", "", "force the loader registered for this extension (e.g. .md) instead of inferring it from each file's extension")
+}
+
+func (a *addCmd) loaderFor(path string) (Loader, error) {
+	if a.loaderExt != "" {
+		return loaderForExt(a.loaderExt)
+	}
+	return LoaderFor(path)
+}
+
 func (a *addCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
 	patterns := f.Args()
 	if len(patterns) == 0 {
@@ -73,9 +138,13 @@ func (a *addCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{})
 				}
 				slog.Info(fmt.Sprintf("found %d files", len(matches)))
 				for _, path := range matches {
-					docs, err := ParseEpub(path)
+					loader, err := a.loaderFor(path)
+					if err != nil {
+						return fmt.Errorf("load %q: %w", path, err)
+					}
+					docs, err := loader.Load(path)
 					if err != nil {
-						return fmt.Errorf("parse %q: %w", path, err)
+						return fmt.Errorf("load %q: %w", path, err)
 					}
 					slog.Info(fmt.Sprintf("import %d chunks", len(docs)))
 					if err := store.Add(ctx, docs...); err != nil {
@@ -97,6 +166,8 @@ func (a *addCmd) Execute(ctx context.Context, f *flag.FlagSet, _ ...interface{})
 type queryCmd struct {
 	chromaStoreCmd
 	nResult int
+	mode    string
+	alpha   float64
 }
 
 func (*queryCmd) Name() string     { return "query" }
@@ -105,6 +176,28 @@ func (*queryCmd) Usage() string    { return "" }
 func (q *queryCmd) SetFlags(f *flag.FlagSet) {
 	q.chromaStoreCmd.SetFlags(f)
 	f.IntVar(&q.nResult, "n", 10, "number of results")
+	f.StringVar(&q.mode, "mode", "hybrid", "retrieval mode: hybrid, vector, or bm25")
+	f.Float64Var(&q.alpha, "alpha", 0, "when > 0, weight (0-1) given to the vector score in hybrid mode, instead of Reciprocal Rank Fusion")
+}
+
+func (q *queryCmd) newQuery(query string) (*Query, error) {
+	nq := NewQuery(query).WithNumber(q.nResult)
+	switch q.mode {
+	case "hybrid":
+		if q.alpha > 0 {
+			nq = nq.WithHybrid(float32(q.alpha))
+		}
+	case "vector":
+		nq = nq.WithVectorOnly()
+	case "bm25":
+		nq = nq.WithBM25Only()
+	default:
+		return nil, fmt.Errorf("unknown mode %q, want hybrid, vector, or bm25", q.mode)
+	}
+	if q.rerankModel != "" {
+		nq = nq.WithRerank(q.nResult)
+	}
+	return nq, nil
 }
 
 func (q *queryCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
@@ -122,7 +215,11 @@ func (q *queryCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{
 		if err != nil {
 			return err
 		}
-		results, err := store.Search(ctx, NewQuery(query).WithNumber(q.nResult))
+		nq, err := q.newQuery(query)
+		if err != nil {
+			return err
+		}
+		results, err := store.Search(ctx, nq)
 		if err != nil {
 			return err
 		}
@@ -148,14 +245,187 @@ func (q *queryCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{
 	return subcommands.ExitSuccess
 }
 
+type askCmd struct {
+	chromaStoreCmd
+	genModel    string
+	nResult     int
+	maxTokens   int
+	temperature float64
+	cite        bool
+	where       keyValueFlag
+	whereRegex  keyValueFlag
+}
+
+func (*askCmd) Name() string     { return "ask" }
+func (*askCmd) Synopsis() string { return "ask a question grounded on the vectorstore" }
+func (*askCmd) Usage() string    { return "" }
+
+// SetFlags registers chromaStoreCmd's flags under -embed-model instead of
+// -model, since ask's own -model names the chat model that generates the
+// answer.
+func (a *askCmd) SetFlags(f *flag.FlagSet) {
+	a.where = make(keyValueFlag)
+	a.whereRegex = make(keyValueFlag)
+	f.StringVar(&a.model, "embed-model", defaultEmbeddingModel, "embedding model used for retrieval")
+	f.StringVar(&a.storeName, "collection", defaultStoreName, "collection of vectorstore")
+	f.StringVar(&a.storeURL, "store-url", "", "vectorstore location, e.g. grpc://host:port/tenant/database/collection (defaults to the local file store)")
+	f.StringVar(&a.rerankModel, "rerank-model", "", "Ollama model used to rerank search results (unset disables reranking)")
+	f.StringVar(&a.genModel, "model", defaultGenModel, "Ollama chat model used to generate the answer")
+	f.IntVar(&a.nResult, "n", 5, "number of context chunks to retrieve")
+	f.IntVar(&a.maxTokens, "max-tokens", 512, "maximum tokens to generate")
+	f.Float64Var(&a.temperature, "temperature", 0.2, "generation temperature")
+	f.BoolVar(&a.cite, "cite", false, "append a sources list to the answer")
+	f.Var(&a.where, "where", "exact metadata filter key=value, e.g. author=Orwell (repeatable)")
+	f.Var(&a.whereRegex, "where-regex", "regex metadata filter key=pattern (repeatable)")
+}
+
+func (a *askCmd) retrieve(ctx context.Context, store VectorStore, question string) ([]Result, error) {
+	q := NewQuery(question).WithNumber(a.nResult)
+	if a.rerankModel != "" {
+		q = q.WithRerank(a.nResult)
+	}
+	if len(a.where) > 0 {
+		q = q.WithExact(a.where.keyValues()...)
+	}
+	if len(a.whereRegex) > 0 {
+		q = q.WithRegex(a.whereRegex.keyValues()...)
+	}
+	return store.Search(ctx, q)
+}
+
+// askSystemPrompt instructs the chat model to answer strictly from the
+// retrieved context and to cite each claim with the [title, author,
+// chunk#] tag of the passage it came from.
+const askSystemPrompt = `You are a research assistant. Answer the question using only the context passages below, each tagged with its [title, author, chunk#]. Cite that tag after any claim drawn from it. If the context does not contain the answer, say so.`
+
+func askMessages(question string, results []Result) []api.Message {
+	var passages strings.Builder
+	for _, r := range results {
+		fmt.Fprintf(&passages, "[%s, %s, %s]\n%s\n\n",
+			r.Metadata["title"], r.Metadata["author"], r.Metadata["chunk"], r.Content)
+	}
+	return []api.Message{
+		{Role: "system", Content: askSystemPrompt},
+		{Role: "user", Content: fmt.Sprintf("Context:\n%s\nQuestion: %s", passages.String(), question)},
+	}
+}
+
+// sources renders a deduplicated "Sources:" list from the chunks used to
+// ground an answer, for askCmd's -cite flag.
+func sources(results []Result) string {
+	seen := make(map[string]bool)
+	var b strings.Builder
+	b.WriteString("Sources:\n")
+	for _, r := range results {
+		tag := fmt.Sprintf("- %s, %s, chunk %s", r.Metadata["title"], r.Metadata["author"], r.Metadata["chunk"])
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		b.WriteString(tag + "\n")
+	}
+	return b.String()
+}
+
+func (a *askCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	var question string
+	form := huh.NewForm(
+		huh.NewGroup(huh.NewInput().Title("question").Value(&question)),
+	)
+	if err := form.Run(); err != nil {
+		slog.Error(err.Error())
+		return subcommands.ExitFailure
+	}
+	var answer string
+	var results []Result
+	if err := spinner.New().Context(ctx).Accessible(false).ActionWithErr(func(ctx context.Context) error {
+		store, err := a.Store()
+		if err != nil {
+			return err
+		}
+		results, err = a.retrieve(ctx, store, question)
+		if err != nil {
+			return err
+		}
+		client, err := api.ClientFromEnvironment()
+		if err != nil {
+			return err
+		}
+		stream := true
+		req := &api.ChatRequest{
+			Model:    a.genModel,
+			Messages: askMessages(question, results),
+			Stream:   &stream,
+			Options: map[string]interface{}{
+				"num_predict": a.maxTokens,
+				"temperature": a.temperature,
+			},
+		}
+		var out strings.Builder
+		if err := client.Chat(ctx, req, func(res api.ChatResponse) error {
+			out.WriteString(res.Message.Content)
+			return nil
+		}); err != nil {
+			return err
+		}
+		answer = out.String()
+		return nil
+	}).Run(); err != nil {
+		slog.Error(err.Error())
+		return subcommands.ExitFailure
+	}
+	if a.cite {
+		answer += "\n\n" + sources(results)
+	}
+	md, err := glamour.Render(answer, "dark")
+	if err != nil {
+		slog.Error(err.Error())
+		return subcommands.ExitFailure
+	}
+	fmt.Println(md)
+	return subcommands.ExitSuccess
+}
+
+type serveCmd struct {
+	chromaStoreCmd
+	addr     string
+	genModel string
+}
+
+func (*serveCmd) Name() string     { return "serve" }
+func (*serveCmd) Synopsis() string { return "serve add/search/ask over HTTP" }
+func (*serveCmd) Usage() string    { return "" }
+
+func (s *serveCmd) SetFlags(f *flag.FlagSet) {
+	s.chromaStoreCmd.SetFlags(f)
+	f.StringVar(&s.addr, "addr", ":8080", "address to listen on")
+	f.StringVar(&s.genModel, "gen-model", defaultGenModel, "Ollama chat model used for /v1/ask")
+}
+
+func (s *serveCmd) Execute(ctx context.Context, _ *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	store, err := s.Store()
+	if err != nil {
+		slog.Error(err.Error())
+		return subcommands.ExitFailure
+	}
+	if err := serve(ctx, s.addr, newAPIServer(store, s.genModel, s.rerankModel != "")); err != nil {
+		slog.Error(err.Error())
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
 func main() {
 	subcommands.Register(subcommands.HelpCommand(), "")
 	subcommands.Register(subcommands.FlagsCommand(), "")
 	subcommands.Register(subcommands.CommandsCommand(), "")
 	subcommands.Register(&addCmd{}, "")
 	subcommands.Register(&queryCmd{}, "")
+	subcommands.Register(&askCmd{}, "")
+	subcommands.Register(&serveCmd{}, "")
 
 	flag.Parse()
-	ctx := context.Background()
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 	os.Exit(int(subcommands.Execute(ctx)))
 }
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_keyValueFlag(t *testing.T) {
+	m := make(keyValueFlag)
+	require.NoError(t, m.Set("author=Orwell"))
+	require.Equal(t, "Orwell", m["author"])
+	require.Error(t, m.Set("invalid"))
+}
+
+func Test_sources(t *testing.T) {
+	results := []Result{
+		{Document: Document{Metadata: map[string]string{"title": "1984", "author": "Orwell", "chunk": "3"}}},
+		{Document: Document{Metadata: map[string]string{"title": "1984", "author": "Orwell", "chunk": "3"}}},
+	}
+	got := sources(results)
+	require.Equal(t, "Sources:\n- 1984, Orwell, chunk 3\n", got)
+}
+
+func Test_askMessages(t *testing.T) {
+	results := []Result{
+		{Document: Document{Content: "Big Brother is watching.", Metadata: map[string]string{"title": "1984", "author": "Orwell", "chunk": "3"}}},
+	}
+	messages := askMessages("Who is watching?", results)
+	require.Len(t, messages, 2)
+	require.Equal(t, "system", messages[0].Role)
+	require.Contains(t, messages[1].Content, "[1984, Orwell, 3]")
+	require.Contains(t, messages[1].Content, "Who is watching?")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfLoader extracts the text of a PDF file and chunks it the same way
+// as markdown, skipping the HTML conversion step since PDFs have no
+// markup to strip.
+type pdfLoader struct{}
+
+func (pdfLoader) Extensions() []string { return []string{".pdf"} }
+
+func (pdfLoader) Load(path string) ([]Document, error) {
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	text, err := r.GetPlainText()
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if _, err := buf.ReadFrom(text); err != nil {
+		return nil, err
+	}
+	return chunkMarkdown(buf.Bytes(), loaderMetadata(path, "application/pdf"))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// minimalPDF is a hand-built single-page PDF (no external tooling) whose
+// content stream renders the text "Hello PDF World", used to exercise
+// pdfLoader without committing a binary fixture.
+const minimalPDF = `%PDF-1.4
+1 0 obj
+<< /Type /Catalog /Pages 2 0 R >>
+endobj
+2 0 obj
+<< /Type /Pages /Kids [3 0 R] /Count 1 >>
+endobj
+3 0 obj
+<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 5 0 R >> >> /MediaBox [0 0 300 144] /Contents 4 0 R >>
+endobj
+4 0 obj
+<< /Length 46 >>
+stream
+BT /F1 24 Tf 72 100 Td (Hello PDF World) Tj ET
+endstream
+endobj
+5 0 obj
+<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>
+endobj
+xref
+0 6
+0000000000 65535 f
+0000000009 00000 n
+0000000058 00000 n
+0000000115 00000 n
+0000000241 00000 n
+0000000337 00000 n
+trailer
+<< /Size 6 /Root 1 0 R >>
+startxref
+407
+%%EOF`
+
+func Test_pdfLoader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "book.pdf")
+	require.NoError(t, os.WriteFile(path, []byte(minimalPDF), 0644))
+	docs, err := (pdfLoader{}).Load(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, docs)
+	require.Contains(t, docs[0].Content, "Hello PDF World")
+	require.Equal(t, path, docs[0].Metadata["source_path"])
+	require.Equal(t, "application/pdf", docs[0].Metadata["mime_type"])
+	require.Contains(t, docs[0].Metadata, "author")
+}
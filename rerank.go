@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ollama/ollama/api"
+)
+
+// Reranker scores how well each candidate chunk answers query, so that
+// Search can reorder coarse top-k retrieval results by relevance rather
+// than raw vector or lexical score.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, results []Result) ([]Result, error)
+}
+
+// rerankCandidateFactor is how many more candidates rerankingStore fetches
+// from the wrapped VectorStore than a rerank query asks for, giving the
+// reranker a wider pool to pick the true top N from.
+const rerankCandidateFactor = 4
+
+// rerankingStore wraps a VectorStore and, for queries built with
+// Query.WithRerank, reranks its results with reranker before trimming to
+// the requested count.
+type rerankingStore struct {
+	VectorStore
+	reranker Reranker
+}
+
+// NewRerankingStore wraps store so that queries built with
+// Query.WithRerank are reranked by r after the underlying Search.
+func NewRerankingStore(store VectorStore, r Reranker) VectorStore {
+	return &rerankingStore{VectorStore: store, reranker: r}
+}
+
+func (s *rerankingStore) Search(ctx context.Context, query *Query) ([]Result, error) {
+	q := query.setOrDefault()
+	if !q.hasRerank {
+		return s.VectorStore.Search(ctx, q)
+	}
+	candidates := *q
+	candidates.Number = q.RerankTopN * rerankCandidateFactor
+	results, err := s.VectorStore.Search(ctx, &candidates)
+	if err != nil {
+		return nil, err
+	}
+	reranked, err := s.reranker.Rerank(ctx, q.Query, results)
+	if err != nil {
+		return nil, err
+	}
+	if len(reranked) > q.RerankTopN {
+		reranked = reranked[:q.RerankTopN]
+	}
+	return reranked, nil
+}
+
+// rerankPrompt asks an instruction-tuned model to act as a cross-encoder,
+// emitting a single relevance score for a (query, passage) pair.
+const rerankPrompt = `Rate how relevant the passage is to the query on a scale from 0 to 1, where 1 means the passage directly answers the query and 0 means it is unrelated. Respond with only the number.
+
+Query: %s
+
+Passage: %s
+
+Score:`
+
+type ollamaReranker struct {
+	client *api.Client
+	model  string
+}
+
+// NewOllamaReranker returns a Reranker that scores each (query, chunk)
+// pair with model, an Ollama instruction-tuned or cross-encoder model.
+func NewOllamaReranker(model string) (Reranker, error) {
+	client, err := api.ClientFromEnvironment()
+	return ollamaReranker{client: client, model: model}, err
+}
+
+func (o ollamaReranker) Rerank(ctx context.Context, query string, results []Result) ([]Result, error) {
+	scored := make([]Result, len(results))
+	copy(scored, results)
+	for i, r := range scored {
+		score, err := o.score(ctx, query, r.Content)
+		if err != nil {
+			return nil, err
+		}
+		scored[i].Score = score
+	}
+	sort.Slice(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	return scored, nil
+}
+
+func (o ollamaReranker) score(ctx context.Context, query, passage string) (float32, error) {
+	var out strings.Builder
+	stream := false
+	req := &api.GenerateRequest{
+		Model:  o.model,
+		Prompt: fmt.Sprintf(rerankPrompt, query, passage),
+		Stream: &stream,
+	}
+	if err := o.client.Generate(ctx, req, func(res api.GenerateResponse) error {
+		out.WriteString(res.Response)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(out.String()), 32)
+	if err != nil {
+		return 0, fmt.Errorf("parse rerank score %q: %w", out.String(), err)
+	}
+	return float32(score), nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	gotNumber int
+}
+
+func (f *fakeStore) Add(context.Context, ...Document) error { return nil }
+
+func (f *fakeStore) Search(_ context.Context, q *Query) ([]Result, error) {
+	f.gotNumber = q.Number
+	results := make([]Result, q.Number)
+	for i := range results {
+		results[i] = Result{ID: string(rune('a' + i)), Score: float32(i)}
+	}
+	return results, nil
+}
+
+type reverseReranker struct{}
+
+func (reverseReranker) Rerank(_ context.Context, _ string, results []Result) ([]Result, error) {
+	reversed := make([]Result, len(results))
+	for i, r := range results {
+		reversed[len(results)-1-i] = r
+	}
+	return reversed, nil
+}
+
+func Test_rerankingStore(t *testing.T) {
+	store := &fakeStore{}
+	reranking := NewRerankingStore(store, reverseReranker{})
+
+	results, err := reranking.Search(t.Context(), NewQuery("q").WithRerank(2))
+	require.NoError(t, err)
+	require.Equal(t, 2*rerankCandidateFactor, store.gotNumber)
+	require.Len(t, results, 2)
+	require.Equal(t, "h", results[0].ID)
+}
+
+func Test_rerankingStore_noRerank(t *testing.T) {
+	store := &fakeStore{}
+	reranking := NewRerankingStore(store, reverseReranker{})
+
+	results, err := reranking.Search(t.Context(), NewQuery("q").WithNumber(3))
+	require.NoError(t, err)
+	require.Equal(t, 3, store.gotNumber)
+	require.Len(t, results, 3)
+}
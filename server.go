@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ollama/ollama/api"
+)
+
+// apiServer implements http.Handler for advisory's HTTP/JSON API,
+// exposing the same add/search/ask operations as the CLI over a small
+// multi-client service. It does not change VectorStore or Query; it is a
+// thin transport wrapping them.
+type apiServer struct {
+	store    VectorStore
+	genModel string
+	rerank   bool
+	mux      *http.ServeMux
+}
+
+// newAPIServer builds the routes for store, using genModel to drive the
+// chat model behind /v1/ask. rerank should be true when store was built
+// with NewRerankingStore, so /v1/search and /v1/ask actually request a
+// reranked pool of candidates instead of relying on the reranking store
+// to silently no-op.
+func newAPIServer(store VectorStore, genModel string, rerank bool) *apiServer {
+	s := &apiServer{store: store, genModel: genModel, rerank: rerank, mux: http.NewServeMux()}
+	s.mux.HandleFunc("POST /v1/documents", s.handleDocuments)
+	s.mux.HandleFunc("POST /v1/search", s.handleSearch)
+	s.mux.HandleFunc("POST /v1/ask", s.handleAsk)
+	s.mux.HandleFunc("GET /v1/collections", s.handleCollections)
+	return s
+}
+
+// statusRecorder captures the status code a handler writes, for request
+// logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush forwards to the wrapped ResponseWriter's http.Flusher, since
+// statusRecorder wraps every handler's ResponseWriter and would
+// otherwise hide it behind the statusRecorder's own method set,
+// breaking handleAsk's SSE streaming.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (s *apiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(rec, r)
+	slog.Info("request", "method", r.Method, "path", r.URL.Path, "status", rec.status, "duration", time.Since(start))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+type addDocumentRequest struct {
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// handleDocuments serves POST /v1/documents. A JSON body adds a single
+// document verbatim; a multipart upload routes each file through the
+// loader registered for its extension.
+func (s *apiServer) handleDocuments(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		s.handleDocumentUpload(w, r)
+		return
+	}
+	var req addDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.store.Add(r.Context(), Document{Content: req.Content, Metadata: req.Metadata}); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *apiServer) handleDocumentUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	for _, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			docs, err := loadUpload(header)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if err := s.store.Add(r.Context(), docs...); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// loadUpload spools an uploaded file to a temp path and runs it through
+// the loader registered for its extension, since Loader.Load works on
+// paths rather than readers.
+func loadUpload(header *multipart.FileHeader) ([]Document, error) {
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	loader, err := LoaderFor(header.Filename)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "advisory-upload")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, filepath.Base(header.Filename))
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(out, f); err != nil {
+		out.Close()
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	return loader.Load(path)
+}
+
+// defaultSearchResults is the number of results a searchRequest asks for
+// when N is omitted or non-positive. chromem-go's QueryWithOptions
+// rejects NResults <= 0, so a bare {"query": "..."} request must not
+// reach it with Number still at its zero value.
+const defaultSearchResults = 5
+
+type searchRequest struct {
+	Query      string            `json:"query"`
+	N          int               `json:"n"`
+	Where      map[string]string `json:"where"`
+	WhereRegex map[string]string `json:"where_regex"`
+}
+
+func (req searchRequest) query(rerank bool) *Query {
+	n := req.N
+	if n <= 0 {
+		n = defaultSearchResults
+	}
+	q := NewQuery(req.Query).WithNumber(n)
+	if rerank {
+		q = q.WithRerank(n)
+	}
+	if len(req.Where) > 0 {
+		kv := make([]string, 0, len(req.Where)*2)
+		for k, v := range req.Where {
+			kv = append(kv, k, v)
+		}
+		q = q.WithExact(kv...)
+	}
+	if len(req.WhereRegex) > 0 {
+		kv := make([]string, 0, len(req.WhereRegex)*2)
+		for k, v := range req.WhereRegex {
+			kv = append(kv, k, v)
+		}
+		q = q.WithRegex(kv...)
+	}
+	return q
+}
+
+// handleSearch serves POST /v1/search, returning the matching Results as
+// JSON.
+func (s *apiServer) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req searchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	results, err := s.store.Search(r.Context(), req.query(s.rerank))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleAsk serves POST /v1/ask, grounding genModel on the top N search
+// results for the question and streaming the answer as it is generated
+// via server-sent events.
+func (s *apiServer) handleAsk(w http.ResponseWriter, r *http.Request) {
+	var req searchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	results, err := s.store.Search(r.Context(), req.query(s.rerank))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	client, err := api.ClientFromEnvironment()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	stream := true
+	chatReq := &api.ChatRequest{
+		Model:    s.genModel,
+		Messages: askMessages(req.Query, results),
+		Stream:   &stream,
+	}
+	err = client.Chat(r.Context(), chatReq, func(res api.ChatResponse) error {
+		fmt.Fprintf(w, "data: %s\n\n", res.Message.Content)
+		flusher.Flush()
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(w, "event: error\ndata: %s\n\n", err.Error())
+		flusher.Flush()
+	}
+}
+
+// handleCollections serves GET /v1/collections, listing the chromem-go
+// collections persisted under ~/.advisory/store.
+func (s *apiServer) handleCollections(w http.ResponseWriter, r *http.Request) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	storePath := filepath.Join(home, ".advisory", "store")
+	entries, err := os.ReadDir(storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode([]string{})
+			return
+		}
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	collections := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			collections = append(collections, e.Name())
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(collections)
+}
+
+// serve runs an HTTP server for apiServer at addr until ctx is canceled,
+// then shuts it down gracefully.
+func serve(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler}
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("listening", "addr", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ollama/ollama/api"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_searchRequest_query(t *testing.T) {
+	req := searchRequest{Query: "blue sky", N: 5, Where: map[string]string{"author": "Orwell"}}
+	q := req.query(false)
+	require.Equal(t, "blue sky", q.Query)
+	require.Equal(t, 5, q.Number)
+	require.Equal(t, "Orwell", q.Exact["author"])
+	require.False(t, q.hasRerank)
+}
+
+func Test_searchRequest_query_defaultsN(t *testing.T) {
+	q := searchRequest{Query: "blue sky"}.query(false)
+	require.Equal(t, defaultSearchResults, q.Number)
+}
+
+func Test_searchRequest_query_rerank(t *testing.T) {
+	q := searchRequest{Query: "blue sky", N: 3}.query(true)
+	require.True(t, q.hasRerank)
+	require.Equal(t, 3, q.RerankTopN)
+}
+
+func Test_statusRecorder(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sr := &statusRecorder{ResponseWriter: rec, status: http.StatusOK}
+	sr.WriteHeader(http.StatusCreated)
+	require.Equal(t, http.StatusCreated, sr.status)
+	require.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func Test_apiServer_handleCollections_missing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := newAPIServer(nil, defaultGenModel, false)
+	req := httptest.NewRequest(http.MethodGet, "/v1/collections", nil)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, "[]", rec.Body.String())
+}
+
+func Test_apiServer_handleDocuments_json(t *testing.T) {
+	store := &fakeStoreExt{}
+	s := newAPIServer(store, defaultGenModel, false)
+	body := strings.NewReader(`{"content":"the sky is blue","metadata":{"author":"Orwell"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/documents", body)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Len(t, store.added, 1)
+	require.Equal(t, "the sky is blue", store.added[0].Content)
+	require.Equal(t, "Orwell", store.added[0].Metadata["author"])
+}
+
+func Test_apiServer_handleDocuments_multipart(t *testing.T) {
+	store := &fakeStoreExt{}
+	s := newAPIServer(store, defaultGenModel, false)
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", "notes.md")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("# Title\n\nSome notes."))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/documents", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.NotEmpty(t, store.added)
+}
+
+func Test_apiServer_handleSearch(t *testing.T) {
+	store := &fakeStore{}
+	s := newAPIServer(store, defaultGenModel, false)
+	body := strings.NewReader(`{"query":"blue sky"}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/search", body)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, defaultSearchResults, store.gotNumber)
+
+	var results []Result
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &results))
+	require.Len(t, results, defaultSearchResults)
+}
+
+func Test_apiServer_handleAsk_sse(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		require.NoError(t, enc.Encode(api.ChatResponse{Message: api.Message{Content: "The sky "}}))
+		require.NoError(t, enc.Encode(api.ChatResponse{Message: api.Message{Content: "is blue."}, Done: true}))
+	}))
+	defer ollama.Close()
+	t.Setenv("OLLAMA_HOST", ollama.URL)
+
+	store := &fakeStore{}
+	s := newAPIServer(store, defaultGenModel, false)
+	body := strings.NewReader(`{"query":"what color is the sky?","n":1}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/ask", body)
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	require.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	require.Equal(t, "data: The sky \n\ndata: is blue.\n\n", rec.Body.String())
+}
+
+// fakeStoreExt extends fakeStore (defined in rerank_test.go) with an Add
+// that records the documents it receives, for handleDocuments coverage.
+type fakeStoreExt struct {
+	fakeStore
+	added []Document
+}
+
+func (f *fakeStoreExt) Add(_ context.Context, docs ...Document) error {
+	f.added = append(f.added, docs...)
+	return nil
+}